@@ -0,0 +1,157 @@
+package objectvisitor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/heptio/developer-dash/pkg/view/component"
+)
+
+// addNode adds a node for co.Object to co.Component, linking it to
+// co.ParentID when set, and returns the new node's id along with the
+// unstructured form of co.Object for use by the queryer.
+func addNode(ctx context.Context, v *DefaultVisitor, co ClusterObject) (string, *unstructured.Unstructured, error) {
+	accessor, ok := co.Object.(metav1.Object)
+	if !ok {
+		return "", nil, errors.Errorf("object %T does not implement metav1.Object", co.Object)
+	}
+
+	apiVersion, kind := co.Object.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+
+	path, err := v.dashConfig.ObjectPath(ctx, apiVersion, kind, accessor.GetName())
+	if err != nil {
+		return "", nil, errors.Wrap(err, "build object path")
+	}
+
+	nodeID := accessor.GetName()
+
+	co.Component.AddNode(nodeID, component.Node{
+		Name:       accessor.GetName(),
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Path:       component.NewLink("", accessor.GetName(), path),
+	})
+
+	if co.ParentID != "" {
+		co.Component.AddEdge(co.ParentID, nodeID, component.EdgeTypeExplicit)
+	}
+
+	u, err := toUnstructured(co.Object)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return nodeID, u, nil
+}
+
+// visitChildren fetches nodeID's children from the queryer and visits each
+// of them in turn, linking them back to nodeID. When co.MetadataOnly is set,
+// children are fetched as partial object metadata instead of full objects.
+//
+// If co.MaxDepth stops the traversal at this node, no children are fetched
+// at all. If co.MaxChildrenPerNode is exceeded, the extra children are
+// collapsed into a single synthetic "more" node instead of being visited.
+func visitChildren(ctx context.Context, v *DefaultVisitor, nodeID string, u *unstructured.Unstructured, co ClusterObject) error {
+	if co.MaxDepth > 0 && co.Depth >= co.MaxDepth {
+		return nil
+	}
+
+	children, err := fetchChildren(ctx, v, u, co.MetadataOnly)
+	if err != nil {
+		return errors.Wrap(err, "fetch children")
+	}
+
+	visible := children
+	if co.MaxChildrenPerNode > 0 && len(children) > co.MaxChildrenPerNode {
+		visible = children[:co.MaxChildrenPerNode]
+		addTruncationNode(co, nodeID, children[co.MaxChildrenPerNode:])
+	}
+
+	for _, child := range visible {
+		childObject := ClusterObject{
+			Object:             child,
+			Component:          co.Component,
+			ParentID:           nodeID,
+			MetadataOnly:       co.MetadataOnly,
+			Depth:              co.Depth + 1,
+			MaxDepth:           co.MaxDepth,
+			MaxChildrenPerNode: co.MaxChildrenPerNode,
+			OnTruncate:         co.OnTruncate,
+		}
+
+		if err := v.Visit(ctx, childObject); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addTruncationNode adds a synthetic node under parentID summarizing hidden,
+// and reports it through co.OnTruncate so the caller can expand it later.
+func addTruncationNode(co ClusterObject, parentID string, hidden []runtime.Object) {
+	moreNodeID := parentID + "-more"
+
+	co.Component.AddNode(moreNodeID, component.Node{
+		Name: fmt.Sprintf("%d more...", len(hidden)),
+	})
+	co.Component.AddEdge(parentID, moreNodeID, component.EdgeTypeExplicit)
+
+	if co.OnTruncate != nil {
+		co.OnTruncate(moreNodeID, TruncatedChildren{
+			ParentID: parentID,
+			Depth:    co.Depth + 1,
+			Hidden:   hidden,
+		})
+	}
+}
+
+// fetchChildren returns u's children as runtime.Objects, using the
+// queryer's metadata-only path when metadataOnly is set.
+func fetchChildren(ctx context.Context, v *DefaultVisitor, u *unstructured.Unstructured, metadataOnly bool) ([]runtime.Object, error) {
+	if metadataOnly {
+		meta, err := v.queryer.ChildrenMeta(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+
+		children := make([]runtime.Object, 0, len(meta))
+		for _, m := range meta {
+			children = append(children, m)
+		}
+
+		return children, nil
+	}
+
+	objects, err := v.queryer.Children(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]runtime.Object, 0, len(objects))
+	for _, object := range objects {
+		children = append(children, object)
+	}
+
+	return children, nil
+}
+
+// toUnstructured converts a typed object into its unstructured form, which
+// is what the queryer operates on.
+func toUnstructured(object runtime.Object) (*unstructured.Unstructured, error) {
+	if u, ok := object.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, errors.Wrap(err, "convert object to unstructured")
+	}
+
+	return &unstructured.Unstructured{Object: m}, nil
+}
@@ -0,0 +1,56 @@
+package objectvisitor
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/developer-dash/internal/config"
+	"github.com/heptio/developer-dash/internal/queryer"
+)
+
+// visitFunc adds object (and any relevant descendants) to object.Component.
+type visitFunc func(ctx context.Context, v *DefaultVisitor, object ClusterObject) error
+
+// DefaultVisitor is the Visitor Octant uses to build resource viewer
+// graphs. Every kind is walked the same way, via the queryer's owner
+// reference graph (visitGeneric); Pod is the one special case, since pods
+// are always leaves.
+type DefaultVisitor struct {
+	dashConfig config.Dash
+	queryer    queryer.Queryer
+
+	handlers map[schema.GroupVersionKind]visitFunc
+}
+
+// NewDefaultVisitor creates a DefaultVisitor backed by q.
+func NewDefaultVisitor(dashConfig config.Dash, q queryer.Queryer) (*DefaultVisitor, error) {
+	if q == nil {
+		return nil, errors.New("queryer is nil")
+	}
+
+	v := &DefaultVisitor{
+		dashConfig: dashConfig,
+		queryer:    q,
+	}
+
+	v.handlers = map[schema.GroupVersionKind]visitFunc{
+		corev1.SchemeGroupVersion.WithKind("Pod"): visitPod,
+	}
+
+	return v, nil
+}
+
+// Visit implements Visitor.
+func (v *DefaultVisitor) Visit(ctx context.Context, object ClusterObject) error {
+	gvk := object.Object.GetObjectKind().GroupVersionKind()
+
+	fn, ok := v.handlers[gvk]
+	if !ok {
+		fn = visitGeneric
+	}
+
+	return fn(ctx, v, object)
+}
@@ -0,0 +1,15 @@
+package objectvisitor
+
+import "context"
+
+// visitGeneric adds object to the graph and walks its children via the
+// queryer's owner-reference walk, without any kind-specific behavior. It is
+// the fallback for kinds that don't have a dedicated handler.
+func visitGeneric(ctx context.Context, v *DefaultVisitor, co ClusterObject) error {
+	nodeID, u, err := addNode(ctx, v, co)
+	if err != nil {
+		return err
+	}
+
+	return visitChildren(ctx, v, nodeID, u, co)
+}
@@ -0,0 +1,59 @@
+package objectvisitor
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/heptio/developer-dash/pkg/view/component"
+)
+
+// ClusterObject is a single object being visited, along with the graph
+// component the visit is building and the node the object was reached
+// from (if any).
+type ClusterObject struct {
+	// Object is the object being visited.
+	Object runtime.Object
+	// Component is the resource viewer component this visit contributes
+	// nodes and edges to.
+	Component *component.ResourceViewer
+	// ParentID is the node id of the object that owns Object, or "" if
+	// Object is the root of the traversal.
+	ParentID string
+	// MetadataOnly, when set, tells the visitor to fetch children using
+	// partial object metadata rather than full objects. It is propagated
+	// to every descendant visited from this object.
+	MetadataOnly bool
+	// Depth is the number of hops Object is from the root of the
+	// traversal. The root is visited at depth 0.
+	Depth int
+	// MaxDepth, when greater than zero, stops the traversal from
+	// descending past that many hops from the root.
+	MaxDepth int
+	// MaxChildrenPerNode, when greater than zero, caps how many of an
+	// object's children are visited directly; the rest are collapsed into
+	// a single synthetic "more" node.
+	MaxChildrenPerNode int
+	// OnTruncate, when set, is called whenever MaxChildrenPerNode causes
+	// children to be collapsed into a synthetic node, so the caller can
+	// remember how to expand it later.
+	OnTruncate func(moreNodeID string, truncated TruncatedChildren)
+}
+
+// TruncatedChildren describes the children of a node that were collapsed
+// into a synthetic "more" node because of ClusterObject.MaxChildrenPerNode.
+type TruncatedChildren struct {
+	// ParentID is the node id of the object the hidden children belong to.
+	ParentID string
+	// Depth is the depth at which the hidden children would have been
+	// visited.
+	Depth int
+	// Hidden are the children that were collapsed.
+	Hidden []runtime.Object
+}
+
+// Visitor visits a cluster object and adds it, along with its relevant
+// descendants, to object.Component.
+type Visitor interface {
+	Visit(ctx context.Context, object ClusterObject) error
+}
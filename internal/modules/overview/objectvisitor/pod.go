@@ -0,0 +1,10 @@
+package objectvisitor
+
+import "context"
+
+// visitPod adds a Pod to the graph. Pods are leaves of the traversal: they
+// have no children of their own.
+func visitPod(ctx context.Context, v *DefaultVisitor, co ClusterObject) error {
+	_, _, err := addNode(ctx, v, co)
+	return err
+}
@@ -0,0 +1,239 @@
+package resourceviewer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	configFake "github.com/heptio/developer-dash/internal/config/fake"
+	"github.com/heptio/developer-dash/internal/modules/overview/objectvisitor"
+	storeFake "github.com/heptio/developer-dash/internal/objectstore/fake"
+	queryerFake "github.com/heptio/developer-dash/internal/queryer/fake"
+	"github.com/heptio/developer-dash/internal/testutil"
+	"github.com/heptio/developer-dash/pkg/objectstoreutil"
+	"github.com/heptio/developer-dash/pkg/view/component"
+)
+
+// Test_ResourceViewer_MaxChildrenPerNode_truncatesAndExpands asserts that a
+// node with more children than MaxChildrenPerNode gets a synthetic "more"
+// node in its place, and that Expand replaces that node with the rest of
+// the children.
+func Test_ResourceViewer_MaxChildrenPerNode_truncatesAndExpands(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", UID: types.UID("deployment")},
+	}
+	pods := []*corev1.Pod{
+		{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-a", UID: types.UID("pod-a")},
+		},
+		{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-b", UID: types.UID("pod-b")},
+		},
+		{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-c", UID: types.UID("pod-c")},
+		},
+	}
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().
+		ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("/path", nil).
+		AnyTimes()
+
+	o := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(o).AnyTimes()
+
+	q := queryerFake.NewMockQueryer(ctrl)
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, deployment)).
+		Return([]*unstructured.Unstructured{
+			testutil.ToUnstructured(t, pods[0]),
+			testutil.ToUnstructured(t, pods[1]),
+			testutil.ToUnstructured(t, pods[2]),
+		}, nil)
+
+	visitor, err := objectvisitor.NewDefaultVisitor(dashConfig, q)
+	require.NoError(t, err)
+
+	rv, err := New(dashConfig, WithVisitor(visitor), WithMaxChildrenPerNode(2))
+	require.NoError(t, err)
+
+	cc, err := rv.Visit(ctx, deployment)
+	require.NoError(t, err)
+
+	rvCC := cc.(*component.ResourceViewer)
+	_, ok := rvCC.Config.Nodes["pod-a"]
+	assert.True(t, ok)
+	_, ok = rvCC.Config.Nodes["pod-b"]
+	assert.True(t, ok)
+	_, ok = rvCC.Config.Nodes["pod-c"]
+	assert.False(t, ok, "pod-c should have been collapsed into a more node")
+
+	moreNode, ok := rvCC.Config.Nodes["deployment-more"]
+	require.True(t, ok)
+	assert.Equal(t, "1 more...", moreNode.Name)
+
+	_, ok = rvCC.Config.Edges["deployment"]["deployment-more"]
+	assert.True(t, ok, "deployment should have an edge to the more node")
+	_, ok = rvCC.Config.Edges["deployment"]["pod-c"]
+	assert.False(t, ok, "deployment shouldn't have an edge directly to pod-c yet")
+
+	expanded, err := rv.Expand(ctx, "deployment-more")
+	require.NoError(t, err)
+
+	expandedCC := expanded.(*component.ResourceViewer)
+	_, ok = expandedCC.Config.Nodes["deployment-more"]
+	assert.False(t, ok, "more node should be gone after expansion")
+
+	_, ok = expandedCC.Config.Nodes["pod-c"]
+	assert.True(t, ok, "pod-c should be visible after expansion")
+
+	_, ok = expandedCC.Config.Edges["deployment"]["deployment-more"]
+	assert.False(t, ok, "edge to the more node should be gone after expansion")
+	_, ok = expandedCC.Config.Edges["deployment"]["pod-c"]
+	assert.True(t, ok, "deployment should have a direct edge to pod-c after expansion")
+}
+
+// Test_ResourceViewer_MaxDepth_stopsTraversal asserts that a ResourceViewer
+// configured with WithMaxDepth doesn't descend past that many hops from the
+// root, and never even queries the queryer for grandchildren.
+func Test_ResourceViewer_MaxDepth_stopsTraversal(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", UID: types.UID("deployment")},
+	}
+	// A ReplicaSet, unlike a Pod, would have its own children queried if
+	// the traversal didn't stop at it.
+	replicaSet := &appsv1.ReplicaSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "replicaset", UID: types.UID("replicaset")},
+	}
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().
+		ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("/path", nil).
+		AnyTimes()
+
+	o := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(o).AnyTimes()
+
+	q := queryerFake.NewMockQueryer(ctrl)
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, deployment)).
+		Return([]*unstructured.Unstructured{testutil.ToUnstructured(t, replicaSet)}, nil)
+	// No Children call for replicaset is expected: traversal must stop at depth 1.
+
+	visitor, err := objectvisitor.NewDefaultVisitor(dashConfig, q)
+	require.NoError(t, err)
+
+	rv, err := New(dashConfig, WithVisitor(visitor), WithMaxDepth(1))
+	require.NoError(t, err)
+
+	cc, err := rv.Visit(ctx, deployment)
+	require.NoError(t, err)
+
+	rvCC := cc.(*component.ResourceViewer)
+	_, ok := rvCC.Config.Nodes["deployment"]
+	assert.True(t, ok)
+	_, ok = rvCC.Config.Nodes["replicaset"]
+	assert.True(t, ok)
+}
+
+// Test_ComponentCache_Expand asserts that expanding a cached graph's "more"
+// node publishes the expanded graph on Updates without a further call to
+// Get.
+func Test_ComponentCache_Expand(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", UID: types.UID("deployment")},
+	}
+	pods := []*corev1.Pod{
+		{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-a", UID: types.UID("pod-a")},
+		},
+		{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-b", UID: types.UID("pod-b")},
+		},
+	}
+
+	ctx := context.TODO()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().
+		ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("/path", nil).
+		AnyTimes()
+
+	o := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(o).AnyTimes()
+
+	q := queryerFake.NewMockQueryer(ctrl)
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, deployment)).
+		Return([]*unstructured.Unstructured{
+			testutil.ToUnstructured(t, pods[0]),
+			testutil.ToUnstructured(t, pods[1]),
+		}, nil)
+
+	c, err := NewComponentCache(dashConfig)
+	require.NoError(t, err)
+	c.SetQueryer(q)
+	c.SetMaxChildrenPerNode(1)
+
+	_, err = c.Get(ctx, deployment)
+	require.NoError(t, err)
+
+	built := (<-c.Updates()).(*component.ResourceViewer)
+	_, ok := built.Config.Nodes["deployment-more"]
+	require.True(t, ok)
+	_, ok = built.Config.Edges["deployment"]["deployment-more"]
+	require.True(t, ok)
+
+	key, err := objectstoreutil.KeyFromObject(deployment)
+	require.NoError(t, err)
+
+	expanded, err := c.Expand(ctx, key, "deployment-more")
+	require.NoError(t, err)
+
+	expandedCC := expanded.(*component.ResourceViewer)
+	_, ok = expandedCC.Config.Nodes["deployment-more"]
+	assert.False(t, ok)
+	_, ok = expandedCC.Config.Nodes["pod-b"]
+	assert.True(t, ok)
+	_, ok = expandedCC.Config.Edges["deployment"]["deployment-more"]
+	assert.False(t, ok, "edge to the more node should be gone after expansion")
+	_, ok = expandedCC.Config.Edges["deployment"]["pod-b"]
+	assert.True(t, ok, "deployment should have a direct edge to pod-b after expansion")
+
+	published := (<-c.Updates()).(*component.ResourceViewer)
+	_, ok = published.Config.Nodes["pod-b"]
+	assert.True(t, ok)
+}
@@ -0,0 +1,143 @@
+package resourceviewer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	configFake "github.com/heptio/developer-dash/internal/config/fake"
+	storeFake "github.com/heptio/developer-dash/internal/objectstore/fake"
+	queryerFake "github.com/heptio/developer-dash/internal/queryer/fake"
+	"github.com/heptio/developer-dash/internal/testutil"
+	"github.com/heptio/developer-dash/pkg/view/component"
+)
+
+// Test_ComponentCache_HandleObjectEvent_refreshesCachedGraph asserts that,
+// after an initial Get, a cached graph updates itself in response to
+// HandleObjectEvent (as the object store's watch machinery would call it)
+// without any further call to Get.
+func Test_ComponentCache_HandleObjectEvent_refreshesCachedGraph(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", UID: types.UID("deployment")},
+	}
+	podA := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-a", UID: types.UID("pod-a")},
+	}
+
+	ctx := context.TODO()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().
+		ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("/path", nil).
+		AnyTimes()
+
+	o := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(o).AnyTimes()
+
+	q := queryerFake.NewMockQueryer(ctrl)
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, deployment)).
+		Return([]*unstructured.Unstructured{testutil.ToUnstructured(t, podA)}, nil)
+	q.EXPECT().Children(gomock.Any(), testutil.ToUnstructured(t, podA)).Return(nil, nil).AnyTimes()
+
+	c, err := NewComponentCache(dashConfig)
+	require.NoError(t, err)
+	c.SetQueryer(q)
+
+	_, err = c.Get(ctx, deployment)
+	require.NoError(t, err)
+
+	built := (<-c.Updates()).(*component.ResourceViewer)
+	_, ok := built.Config.Nodes["pod-a"]
+	assert.True(t, ok)
+
+	// A second pod owned by the deployment is created and the object store
+	// notifies the cache of it. The new pod isn't a member of any cached
+	// graph yet, but its owner reference names the deployment, so the
+	// deployment's graph should still be rebuilt.
+	podB := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-b",
+			UID:  types.UID("pod-b"),
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "deployment", UID: types.UID("deployment")},
+			},
+		},
+	}
+
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, deployment)).
+		Return([]*unstructured.Unstructured{testutil.ToUnstructured(t, podA), testutil.ToUnstructured(t, podB)}, nil)
+	q.EXPECT().Children(gomock.Any(), testutil.ToUnstructured(t, podB)).Return(nil, nil).AnyTimes()
+
+	require.NoError(t, c.HandleObjectEvent(ctx, podB))
+
+	refreshed := (<-c.Updates()).(*component.ResourceViewer)
+	_, ok = refreshed.Config.Nodes["pod-b"]
+	assert.True(t, ok)
+}
+
+// Test_ComponentCache_HandleObjectEvent_unrelatedObject asserts that an
+// event for an object unrelated to any cached graph doesn't trigger a
+// rebuild.
+func Test_ComponentCache_HandleObjectEvent_unrelatedObject(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", UID: types.UID("deployment")},
+	}
+
+	ctx := context.TODO()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().
+		ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("/path", nil).
+		AnyTimes()
+
+	o := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(o).AnyTimes()
+
+	q := queryerFake.NewMockQueryer(ctrl)
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, deployment)).
+		Return(nil, nil)
+
+	c, err := NewComponentCache(dashConfig)
+	require.NoError(t, err)
+	c.SetQueryer(q)
+
+	_, err = c.Get(ctx, deployment)
+	require.NoError(t, err)
+	<-c.Updates()
+
+	unrelated := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated-pod", UID: types.UID("unrelated-pod")},
+	}
+
+	require.NoError(t, c.HandleObjectEvent(ctx, unrelated))
+
+	select {
+	case cc := <-c.Updates():
+		t.Fatalf("expected no refresh for an unrelated object, got %v", cc)
+	default:
+	}
+}
@@ -0,0 +1,227 @@
+package resourceviewer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	configFake "github.com/heptio/developer-dash/internal/config/fake"
+	"github.com/heptio/developer-dash/internal/modules/overview/objectvisitor"
+	storeFake "github.com/heptio/developer-dash/internal/objectstore/fake"
+	queryerFake "github.com/heptio/developer-dash/internal/queryer/fake"
+	"github.com/heptio/developer-dash/pkg/view/component"
+)
+
+func deploymentFixture(name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)},
+	}
+}
+
+// namespacePods builds full Pod objects with a realistic Spec and Status -
+// several containers, volumes, and status conditions - so that benchmarking
+// against namespacePodsMeta's bare ObjectMeta actually exercises the weight
+// a real cluster's full objects carry, rather than comparing two equally
+// empty structs.
+func namespacePods(n int) []*corev1.Pod {
+	pods := make([]*corev1.Pod, 0, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("pod-%d", i)
+		pods = append(pods, &corev1.Pod{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				UID:    types.UID(name),
+				Labels: map[string]string{"app": "octant", "pod-template-hash": "abc123"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  "app",
+						Image: "example.com/octant/app:latest",
+						Env: []corev1.EnvVar{
+							{Name: "LOG_LEVEL", Value: "info"},
+							{Name: "PORT", Value: "8080"},
+						},
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "config", MountPath: "/etc/octant"},
+						},
+					},
+					{
+						Name:  "sidecar",
+						Image: "example.com/octant/sidecar:latest",
+					},
+				},
+				Volumes: []corev1.Volume{
+					{Name: "config", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+					{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", Ready: true, RestartCount: 0},
+					{Name: "sidecar", Ready: true, RestartCount: 0},
+				},
+			},
+		})
+	}
+	return pods
+}
+
+func namespacePodsMeta(n int) []*metav1.PartialObjectMetadata {
+	pods := make([]*metav1.PartialObjectMetadata, 0, n)
+	for i := 0; i < n; i++ {
+		pods = append(pods, &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("pod-%d", i),
+				UID:  types.UID(fmt.Sprintf("pod-%d", i)),
+			},
+		})
+	}
+	return pods
+}
+
+// asUnstructured converts an object to its unstructured form the same way
+// the queryer's real implementation would before returning it.
+func asUnstructured(object runtime.Object) *unstructured.Unstructured {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		panic(err)
+	}
+
+	return &unstructured.Unstructured{Object: m}
+}
+
+func toUnstructuredPods(pods []*corev1.Pod) []*unstructured.Unstructured {
+	out := make([]*unstructured.Unstructured, 0, len(pods))
+	for _, pod := range pods {
+		out = append(out, asUnstructured(pod))
+	}
+	return out
+}
+
+// Test_ResourceViewer_MetadataOnly_matchesFullObjectTopology checks that
+// metadata-only traversal produces the same set of graph nodes as full
+// object traversal, for the same underlying set of children.
+func Test_ResourceViewer_MetadataOnly_matchesFullObjectTopology(t *testing.T) {
+	deployment := deploymentFixture("deployment")
+	pods := namespacePods(25)
+	podsMeta := namespacePodsMeta(25)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("/path", nil).AnyTimes()
+	objectStore := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(objectStore).AnyTimes()
+
+	fullQueryer := queryerFake.NewMockQueryer(ctrl)
+	fullQueryer.EXPECT().Children(gomock.Any(), asUnstructured(deployment)).Return(toUnstructuredPods(pods), nil)
+
+	fullVisitor, err := objectvisitor.NewDefaultVisitor(dashConfig, fullQueryer)
+	require.NoError(t, err)
+
+	fullRV, err := New(dashConfig, WithVisitor(fullVisitor))
+	require.NoError(t, err)
+
+	fullComponent, err := fullRV.Visit(context.Background(), deployment)
+	require.NoError(t, err)
+
+	metaQueryer := queryerFake.NewMockQueryer(ctrl)
+	metaQueryer.EXPECT().ChildrenMeta(gomock.Any(), asUnstructured(deployment)).Return(podsMeta, nil)
+
+	metaVisitor, err := objectvisitor.NewDefaultVisitor(dashConfig, metaQueryer)
+	require.NoError(t, err)
+
+	metaRV, err := New(dashConfig, WithVisitor(metaVisitor), WithMetadataOnlyTraversal())
+	require.NoError(t, err)
+
+	metaComponent, err := metaRV.Visit(context.Background(), deployment)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, nodeIDs(t, fullComponent), nodeIDs(t, metaComponent))
+}
+
+func nodeIDs(t *testing.T, c component.Component) []string {
+	rvCC, ok := c.(*component.ResourceViewer)
+	require.True(t, ok)
+
+	ids := make([]string, 0, len(rvCC.Config.Nodes))
+	for id := range rvCC.Config.Nodes {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// BenchmarkResourceViewer_Visit_FullObjects and
+// BenchmarkResourceViewer_Visit_MetadataOnly compare the cost of building a
+// resource viewer graph for a namespace with 500 pods using full objects
+// (realistic Spec/Status, as a cluster would return them) versus partial
+// object metadata (ObjectMeta only, as the metadata-only queryer path
+// would). The gap between them reflects the cost of carrying full pod
+// bodies through the traversal, not just the node-building work they share.
+func BenchmarkResourceViewer_Visit_FullObjects(b *testing.B) {
+	benchmarkVisit(b, false)
+}
+
+func BenchmarkResourceViewer_Visit_MetadataOnly(b *testing.B) {
+	benchmarkVisit(b, true)
+}
+
+func benchmarkVisit(b *testing.B, metadataOnly bool) {
+	deployment := deploymentFixture("deployment")
+	pods := namespacePods(500)
+	podsMeta := namespacePodsMeta(500)
+
+	ctrl := gomock.NewController(b)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("/path", nil).AnyTimes()
+	objectStore := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(objectStore).AnyTimes()
+
+	q := queryerFake.NewMockQueryer(ctrl)
+	q.EXPECT().Children(gomock.Any(), gomock.Any()).Return(toUnstructuredPods(pods), nil).AnyTimes()
+	q.EXPECT().ChildrenMeta(gomock.Any(), gomock.Any()).Return(podsMeta, nil).AnyTimes()
+
+	visitor, err := objectvisitor.NewDefaultVisitor(dashConfig, q)
+	require.NoError(b, err)
+
+	opts := []ViewerOpt{WithVisitor(visitor)}
+	if metadataOnly {
+		opts = append(opts, WithMetadataOnlyTraversal())
+	}
+
+	rv, err := New(dashConfig, opts...)
+	require.NoError(b, err)
+
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := rv.Visit(ctx, deployment); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,528 @@
+package resourceviewer
+
+import (
+	"context"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/heptio/developer-dash/internal/config"
+	"github.com/heptio/developer-dash/internal/modules/overview/objectvisitor"
+	"github.com/heptio/developer-dash/internal/queryer"
+	"github.com/heptio/developer-dash/pkg/objectstoreutil"
+	"github.com/heptio/developer-dash/pkg/view/component"
+)
+
+// emptyID is the node id used for the placeholder node shown for an object
+// whose resource viewer graph hasn't finished building yet.
+const emptyID = "emptyID"
+
+// ViewerOpt configures a ResourceViewer.
+type ViewerOpt func(rv *ResourceViewer) error
+
+// WithVisitor sets the visitor a ResourceViewer uses to build its graph.
+func WithVisitor(visitor objectvisitor.Visitor) ViewerOpt {
+	return func(rv *ResourceViewer) error {
+		rv.visitor = visitor
+		return nil
+	}
+}
+
+// WithMetadataOnlyTraversal makes the ResourceViewer fetch children via the
+// queryer's metadata-only path (partial object metadata) instead of full
+// objects. This is much cheaper on namespaces with many objects. Child nodes
+// are built from that metadata alone; their full objects are not fetched
+// later, not even when a "more" node is expanded.
+func WithMetadataOnlyTraversal() ViewerOpt {
+	return func(rv *ResourceViewer) error {
+		rv.metadataOnly = true
+		return nil
+	}
+}
+
+// WithMaxDepth stops traversal from descending more than n hops from the
+// root object.
+func WithMaxDepth(n int) ViewerOpt {
+	return func(rv *ResourceViewer) error {
+		rv.maxDepth = n
+		return nil
+	}
+}
+
+// WithMaxChildrenPerNode caps how many of a node's children are visited
+// directly; the rest are collapsed into a single "more" node that can later
+// be expanded with Expand.
+func WithMaxChildrenPerNode(n int) ViewerOpt {
+	return func(rv *ResourceViewer) error {
+		rv.maxChildrenPerNode = n
+		return nil
+	}
+}
+
+// ResourceViewer visits an object and the resources it owns (and those they
+// own, in turn) and builds a component describing the relationships between
+// them. Owners of the object are not visited.
+type ResourceViewer struct {
+	dashConfig         config.Dash
+	visitor            objectvisitor.Visitor
+	metadataOnly       bool
+	maxDepth           int
+	maxChildrenPerNode int
+
+	// mu guards lastComponent and pending, which Visit and Expand both
+	// read and mutate. A single ResourceViewer can be visited and expanded
+	// from different goroutines (a background refresh racing a request to
+	// expand a node), so access to this state must be synchronized.
+	mu sync.Mutex
+	// lastComponent and pending remember the result of the most recent
+	// Visit call so a later Expand can merge into the same component.
+	lastComponent *component.ResourceViewer
+	pending       map[string]pendingExpansion
+}
+
+// pendingExpansion is what's needed to expand a synthetic "more" node into
+// the nodes it was collapsed from.
+type pendingExpansion struct {
+	parentID string
+	depth    int
+	children []runtime.Object
+}
+
+// New creates a ResourceViewer. A visitor must be supplied via ViewerOpt
+// (WithVisitor, in production use the one returned by
+// objectvisitor.NewDefaultVisitor).
+func New(dashConfig config.Dash, opts ...ViewerOpt) (*ResourceViewer, error) {
+	rv := &ResourceViewer{
+		dashConfig: dashConfig,
+	}
+
+	for _, opt := range opts {
+		if err := opt(rv); err != nil {
+			return nil, err
+		}
+	}
+
+	if rv.visitor == nil {
+		return nil, errors.New("resource viewer visitor was not configured")
+	}
+
+	return rv, nil
+}
+
+// Visit builds a resource viewer component rooted at object. If
+// WithMaxDepth or WithMaxChildrenPerNode truncated part of the graph, the
+// truncated nodes can later be expanded with Expand.
+func (rv *ResourceViewer) Visit(ctx context.Context, object runtime.Object) (component.Component, error) {
+	cc := component.NewResourceViewer("Resource Viewer")
+	pending := make(map[string]pendingExpansion)
+
+	co := objectvisitor.ClusterObject{
+		Object:             object,
+		Component:          cc,
+		MetadataOnly:       rv.metadataOnly,
+		MaxDepth:           rv.maxDepth,
+		MaxChildrenPerNode: rv.maxChildrenPerNode,
+		OnTruncate: func(moreNodeID string, truncated objectvisitor.TruncatedChildren) {
+			pending[moreNodeID] = pendingExpansion{
+				parentID: truncated.ParentID,
+				depth:    truncated.Depth,
+				children: truncated.Hidden,
+			}
+		},
+	}
+
+	if err := rv.visitor.Visit(ctx, co); err != nil {
+		return nil, errors.Wrap(err, "visit object")
+	}
+
+	rv.mu.Lock()
+	rv.lastComponent = cc
+	rv.pending = pending
+	rv.mu.Unlock()
+
+	return cc, nil
+}
+
+// Expand replaces the synthetic "more" node identified by nodeID with the
+// children it was collapsed from, visiting each of them (and re-applying
+// any depth/fan-out limits from their point in the graph). It must be
+// called after a Visit that produced a "more" node with that id.
+func (rv *ResourceViewer) Expand(ctx context.Context, nodeID string) (component.Component, error) {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+
+	if rv.lastComponent == nil {
+		return nil, errors.New("resource viewer has not visited an object yet")
+	}
+
+	expansion, ok := rv.pending[nodeID]
+	if !ok {
+		return nil, errors.Errorf("no pending expansion for node %q", nodeID)
+	}
+
+	delete(rv.lastComponent.Config.Nodes, nodeID)
+	if parentEdges, ok := rv.lastComponent.Config.Edges[expansion.parentID]; ok {
+		delete(parentEdges, nodeID)
+	}
+	delete(rv.lastComponent.Config.Edges, nodeID)
+	delete(rv.pending, nodeID)
+
+	for _, child := range expansion.children {
+		co := objectvisitor.ClusterObject{
+			Object:             child,
+			Component:          rv.lastComponent,
+			ParentID:           expansion.parentID,
+			MetadataOnly:       rv.metadataOnly,
+			Depth:              expansion.depth,
+			MaxDepth:           rv.maxDepth,
+			MaxChildrenPerNode: rv.maxChildrenPerNode,
+			OnTruncate: func(moreNodeID string, truncated objectvisitor.TruncatedChildren) {
+				rv.pending[moreNodeID] = pendingExpansion{
+					parentID: truncated.ParentID,
+					depth:    truncated.Depth,
+					children: truncated.Hidden,
+				}
+			},
+		}
+
+		if err := rv.visitor.Visit(ctx, co); err != nil {
+			return nil, errors.Wrap(err, "expand node")
+		}
+	}
+
+	return rv.lastComponent, nil
+}
+
+// componentCache caches resource viewer components, keyed by the object
+// they were built for.
+type componentCache struct {
+	components         *lru.Cache
+	dashConfig         config.Dash
+	queryer            queryer.Queryer
+	metadataOnly       bool
+	maxDepth           int
+	maxChildrenPerNode int
+
+	mu    sync.Mutex
+	roots map[objectstoreutil.Key]*cachedRoot
+
+	updates chan component.Component
+}
+
+// cachedRoot remembers enough about a previously built graph to refresh it
+// later: the root object and resource viewer used to build it, and the keys
+// of every object that ended up as a node in it.
+type cachedRoot struct {
+	object  runtime.Object
+	rv      *ResourceViewer
+	members map[objectstoreutil.Key]bool
+}
+
+// NewComponentCache creates a componentCache.
+func NewComponentCache(dashConfig config.Dash) (*componentCache, error) {
+	components, err := lru.New(500)
+	if err != nil {
+		return nil, errors.Wrap(err, "create component cache")
+	}
+
+	return &componentCache{
+		components: components,
+		dashConfig: dashConfig,
+		roots:      make(map[objectstoreutil.Key]*cachedRoot),
+		updates:    make(chan component.Component, 10),
+	}, nil
+}
+
+// Updates returns a channel that receives a component every time a
+// background refresh (triggered by Get or HandleObjectEvent) or an Expand
+// finishes. The channel buffers 10 components; while it is full, further
+// components are dropped.
+func (c *componentCache) Updates() <-chan component.Component {
+	return c.updates
+}
+
+// publish pushes cc to Updates without blocking if nobody is listening.
+func (c *componentCache) publish(cc component.Component) {
+	select {
+	case c.updates <- cc:
+	default:
+	}
+}
+
+// SetQueryer sets the queryer used to discover object relationships.
+func (c *componentCache) SetQueryer(q queryer.Queryer) {
+	c.queryer = q
+}
+
+// SetMetadataOnlyTraversal controls whether resource viewers built by this
+// cache fetch children via the queryer's metadata-only path. See
+// WithMetadataOnlyTraversal.
+func (c *componentCache) SetMetadataOnlyTraversal(metadataOnly bool) {
+	c.metadataOnly = metadataOnly
+}
+
+// SetMaxDepth controls how many hops from the root object resource viewers
+// built by this cache will traverse. See WithMaxDepth.
+func (c *componentCache) SetMaxDepth(n int) {
+	c.maxDepth = n
+}
+
+// SetMaxChildrenPerNode controls how many children of a node resource
+// viewers built by this cache will visit directly. See
+// WithMaxChildrenPerNode.
+func (c *componentCache) SetMaxChildrenPerNode(n int) {
+	c.maxChildrenPerNode = n
+}
+
+// Get returns the resource viewer component for object. If a graph has
+// already been built for object, the last-known-good version is returned
+// immediately. Otherwise a placeholder is returned immediately and a full
+// build runs in the background, publishing its result on Updates once done.
+func (c *componentCache) Get(ctx context.Context, object runtime.Object) (component.Component, error) {
+	if c.queryer == nil {
+		return nil, errors.New("componentCache queryer was not set")
+	}
+
+	rv, err := c.newResourceViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := objectstoreutil.KeyFromObject(object)
+	if err != nil {
+		return nil, errors.Wrap(err, "create key for object")
+	}
+
+	// A built graph can have been evicted from components while its root is
+	// still recorded; rebuild it in that case too.
+	built := c.hasRoot(key) && c.components.Contains(key)
+
+	cc, err := c.getComponent(ctx, key, object, rv)
+	if err != nil {
+		return nil, err
+	}
+
+	if !built {
+		c.refreshAsync(ctx, key, object, rv)
+	}
+
+	return cc, nil
+}
+
+// hasRoot reports whether a full graph has already been built for key.
+func (c *componentCache) hasRoot(key objectstoreutil.Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.roots[key]
+	return ok
+}
+
+// refreshAsync starts a background build for key and publishes the result on
+// Updates once it finishes. A build already running for key is not
+// cancelled. Nothing is published if the build fails.
+func (c *componentCache) refreshAsync(ctx context.Context, key objectstoreutil.Key, object runtime.Object, rv *ResourceViewer) {
+	go func() {
+		done, err := c.visit(ctx, key, object, rv)
+		if err != nil {
+			return
+		}
+
+		newKey, ok := <-done
+		if !ok {
+			return
+		}
+
+		updated, err := c.getComponent(ctx, newKey, object, rv)
+		if err != nil {
+			return
+		}
+
+		c.publish(updated)
+	}()
+}
+
+// HandleObjectEvent is called whenever the cluster's object store reports
+// that object was added, updated, or deleted. Every cached root whose
+// subtree contains object is rebuilt in the background. A root's subtree
+// contains object if object is the root or a node already recorded as a
+// member of that root's graph, or if one of object's direct owner
+// references names one - the latter covers objects that were just added and
+// so aren't members yet. Owners further up the chain are not looked up.
+func (c *componentCache) HandleObjectEvent(ctx context.Context, object runtime.Object) error {
+	changedKey, err := objectstoreutil.KeyFromObject(object)
+	if err != nil {
+		return errors.Wrap(err, "create key for changed object")
+	}
+
+	accessor, ok := object.(metav1.Object)
+	if !ok {
+		return errors.Errorf("object %T does not implement metav1.Object", object)
+	}
+
+	// Owners are always in the same namespace as the object that
+	// references them, so reuse the changed object's namespace here.
+	candidates := []objectstoreutil.Key{changedKey}
+	for _, ref := range accessor.GetOwnerReferences() {
+		candidates = append(candidates, objectstoreutil.Key{
+			Namespace:  accessor.GetNamespace(),
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+			Name:       ref.Name,
+		})
+	}
+
+	c.mu.Lock()
+	var affected []objectstoreutil.Key
+	for rootKey, root := range c.roots {
+		for _, candidate := range candidates {
+			if rootKey == candidate || root.members[candidate] {
+				affected = append(affected, rootKey)
+				break
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	for _, rootKey := range affected {
+		c.mu.Lock()
+		root := c.roots[rootKey]
+		c.mu.Unlock()
+
+		c.refreshAsync(ctx, rootKey, root.object, root.rv)
+	}
+
+	return nil
+}
+
+// newResourceViewer creates a ResourceViewer configured with the default
+// visitor, wired to this cache's queryer.
+func (c *componentCache) newResourceViewer(ctx context.Context) (*ResourceViewer, error) {
+	if c.queryer == nil {
+		return nil, errors.New("componentCache queryer was not set")
+	}
+
+	visitor, err := objectvisitor.NewDefaultVisitor(c.dashConfig, c.queryer)
+	if err != nil {
+		return nil, errors.Wrap(err, "create default visitor")
+	}
+
+	opts := []ViewerOpt{WithVisitor(visitor)}
+	if c.metadataOnly {
+		opts = append(opts, WithMetadataOnlyTraversal())
+	}
+	if c.maxDepth > 0 {
+		opts = append(opts, WithMaxDepth(c.maxDepth))
+	}
+	if c.maxChildrenPerNode > 0 {
+		opts = append(opts, WithMaxChildrenPerNode(c.maxChildrenPerNode))
+	}
+
+	return New(c.dashConfig, opts...)
+}
+
+// getComponent returns the cached component for key, creating a placeholder
+// component for object if nothing is cached yet.
+func (c *componentCache) getComponent(ctx context.Context, key objectstoreutil.Key, object runtime.Object, rv *ResourceViewer) (component.Component, error) {
+	if v, ok := c.components.Get(key); ok {
+		return v.(component.Component), nil
+	}
+
+	accessor, ok := object.(metav1.Object)
+	if !ok {
+		return nil, errors.Errorf("object %T does not implement metav1.Object", object)
+	}
+
+	cc := component.NewResourceViewer("Resource Viewer")
+	cc.AddNode(emptyID, component.Node{Name: accessor.GetName()})
+
+	c.components.Add(key, cc)
+
+	return cc, nil
+}
+
+// visit asynchronously builds the full graph for object and stores it in
+// the cache under key. The returned channel receives key once the new
+// component has been stored.
+func (c *componentCache) visit(ctx context.Context, key objectstoreutil.Key, object runtime.Object, rv *ResourceViewer) (chan objectstoreutil.Key, error) {
+	done := make(chan objectstoreutil.Key, 1)
+
+	go func() {
+		defer close(done)
+
+		cc, err := rv.Visit(ctx, object)
+		if err != nil {
+			return
+		}
+
+		c.components.Add(key, cc)
+		c.recordRoot(key, object, rv, cc)
+		done <- key
+	}()
+
+	return done, nil
+}
+
+// recordRoot remembers object's root key and resource viewer, along with
+// the keys of every node in cc, so a later HandleObjectEvent call can find
+// and rebuild the graphs a changed object belongs to.
+func (c *componentCache) recordRoot(key objectstoreutil.Key, object runtime.Object, rv *ResourceViewer, cc component.Component) {
+	rvCC, ok := cc.(*component.ResourceViewer)
+	if !ok {
+		return
+	}
+
+	// Every node reachable from object is one of its owned resources, and
+	// owned resources always live in the same namespace as their owner, so
+	// members share object's namespace even though component.Node itself
+	// doesn't record one.
+	accessor, ok := object.(metav1.Object)
+	if !ok {
+		return
+	}
+	namespace := accessor.GetNamespace()
+
+	members := make(map[objectstoreutil.Key]bool, len(rvCC.Config.Nodes))
+	for _, node := range rvCC.Config.Nodes {
+		// Synthetic "more" nodes added by addTruncationNode carry no
+		// APIVersion/Kind, since they don't correspond to a real cluster
+		// object; skip them so they don't pollute the membership set with
+		// a junk key.
+		if node.Kind == "" {
+			continue
+		}
+
+		members[objectstoreutil.Key{Namespace: namespace, APIVersion: node.APIVersion, Kind: node.Kind, Name: node.Name}] = true
+	}
+
+	c.mu.Lock()
+	c.roots[key] = &cachedRoot{object: object, rv: rv, members: members}
+	c.mu.Unlock()
+}
+
+// Expand expands the synthetic "more" node nodeID in the graph cached under
+// key, merging the expanded nodes into that cached graph and publishing the
+// result on Updates.
+func (c *componentCache) Expand(ctx context.Context, key objectstoreutil.Key, nodeID string) (component.Component, error) {
+	c.mu.Lock()
+	root, ok := c.roots[key]
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, errors.Errorf("no cached graph for key %v", key)
+	}
+
+	cc, err := root.rv.Expand(ctx, nodeID)
+	if err != nil {
+		return nil, errors.Wrap(err, "expand node")
+	}
+
+	c.components.Add(key, cc)
+	c.recordRoot(key, root.object, root.rv, cc)
+	c.publish(cc)
+
+	return cc, nil
+}
@@ -10,7 +10,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
 	configFake "github.com/heptio/developer-dash/internal/config/fake"
@@ -126,12 +131,17 @@ func Test_ComponentCache_Get(t *testing.T) {
 
 	rvComponent, err := c.Get(ctx, deployment)
 	require.NoError(t, err)
+	assert.NotNil(t, rvComponent)
 
-	metadata := rvComponent.GetMetadata()
+	// Get returns immediately; wait for the background build it kicked off
+	// to publish the fully built graph.
+	updated := <-c.Updates()
+
+	metadata := updated.GetMetadata()
 	text := metadata.Title[0].(*component.Text)
 
 	assert.Equal(t, "resourceViewer", metadata.Type)
-	assert.Equal(t, rvComponent.IsEmpty(), false)
+	assert.Equal(t, updated.IsEmpty(), false)
 	assert.Equal(t, text.Config.Text, "Resource Viewer")
 }
 
@@ -263,4 +273,242 @@ func Test_ComponentCache_visit(t *testing.T) {
 
 	node, ok = rvCC.Config.Nodes["deployment"]
 	assert.Equal(t, "deployment", node.Name)
-}
\ No newline at end of file
+}
+
+// componentCacheVisitTestCase runs the same assertions as
+// Test_ComponentCache_visit, but parameterized by root object and its
+// expected node id, so each new traversal root kind is covered the same
+// way Deployment already is.
+func componentCacheVisitTestCase(t *testing.T, object runtime.Object, nodeName string) {
+	ctx := context.TODO()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accessor := object.(metav1.Object)
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().
+		ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), accessor.GetName()).
+		Return("/path", nil)
+
+	q := queryerFake.NewMockQueryer(ctrl)
+
+	o := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(o).AnyTimes()
+
+	components, err := lru.New(100)
+	require.NoError(t, err)
+
+	c := &componentCache{
+		components: components,
+		dashConfig: dashConfig,
+	}
+	c.SetQueryer(q)
+
+	rv, err := c.newResourceViewer(ctx)
+	require.NoError(t, err)
+
+	key, err := objectstoreutil.KeyFromObject(object)
+	require.NoError(t, err)
+
+	cc, err := c.getComponent(ctx, key, object, rv)
+	require.NoError(t, err)
+
+	rvCC := cc.(*component.ResourceViewer)
+	node, ok := rvCC.Config.Nodes["emptyID"]
+	assert.Equal(t, ok, true)
+	assert.Equal(t, nodeName, node.Name)
+
+	q.EXPECT().Children(gomock.Any(), testutil.ToUnstructured(t, object))
+
+	done, _ := c.visit(ctx, key, object, rv)
+	newKey := <-done
+
+	cc, err = c.getComponent(ctx, newKey, object, rv)
+	require.NoError(t, err)
+
+	rvCC = cc.(*component.ResourceViewer)
+	_, ok = rvCC.Config.Nodes["emptyID"]
+	assert.Equal(t, ok, false)
+
+	node, ok = rvCC.Config.Nodes[nodeName]
+	assert.Equal(t, true, ok)
+	assert.Equal(t, nodeName, node.Name)
+}
+
+func Test_ComponentCache_visit_CronJob(t *testing.T) {
+	cronJob := &batchv1beta1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1beta1", Kind: "CronJob"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cronjob", UID: types.UID("cronjob")},
+	}
+
+	componentCacheVisitTestCase(t, cronJob, "cronjob")
+}
+
+func Test_ComponentCache_visit_Job(t *testing.T) {
+	job := &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{Name: "job", UID: types.UID("job")},
+	}
+
+	componentCacheVisitTestCase(t, job, "job")
+}
+
+func Test_ComponentCache_visit_DaemonSet(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "daemonset", UID: types.UID("daemonset")},
+	}
+
+	componentCacheVisitTestCase(t, daemonSet, "daemonset")
+}
+
+func Test_ComponentCache_visit_ReplicaSet(t *testing.T) {
+	replicaSet := &appsv1.ReplicaSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "replicaset", UID: types.UID("replicaset")},
+	}
+
+	componentCacheVisitTestCase(t, replicaSet, "replicaset")
+}
+
+func Test_ComponentCache_visit_StatefulSet(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "statefulset", UID: types.UID("statefulset")},
+	}
+
+	componentCacheVisitTestCase(t, statefulSet, "statefulset")
+}
+
+// Test_ComponentCache_visit_CronJob_withDescendants covers the owner-ref
+// chain a CronJob root is expected to walk: CronJob -> Job -> Pod. Unlike
+// componentCacheVisitTestCase, the queryer mock here actually returns
+// descendants, so the traversal across two levels is exercised, not just
+// the root node.
+func Test_ComponentCache_visit_CronJob_withDescendants(t *testing.T) {
+	cronJob := &batchv1beta1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1beta1", Kind: "CronJob"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cronjob", UID: types.UID("cronjob")},
+	}
+	job := &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{Name: "job", UID: types.UID("job")},
+	}
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: types.UID("pod")},
+	}
+
+	ctx := context.TODO()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().
+		ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("/path", nil).
+		AnyTimes()
+
+	o := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(o).AnyTimes()
+
+	q := queryerFake.NewMockQueryer(ctrl)
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, cronJob)).
+		Return([]*unstructured.Unstructured{testutil.ToUnstructured(t, job)}, nil)
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, job)).
+		Return([]*unstructured.Unstructured{testutil.ToUnstructured(t, pod)}, nil)
+
+	components, err := lru.New(100)
+	require.NoError(t, err)
+
+	c := &componentCache{
+		components: components,
+		dashConfig: dashConfig,
+	}
+	c.SetQueryer(q)
+
+	rv, err := c.newResourceViewer(ctx)
+	require.NoError(t, err)
+
+	key, err := objectstoreutil.KeyFromObject(cronJob)
+	require.NoError(t, err)
+
+	done, _ := c.visit(ctx, key, cronJob, rv)
+	newKey := <-done
+
+	cc, err := c.getComponent(ctx, newKey, cronJob, rv)
+	require.NoError(t, err)
+
+	rvCC := cc.(*component.ResourceViewer)
+	_, ok := rvCC.Config.Nodes["cronjob"]
+	assert.True(t, ok)
+	_, ok = rvCC.Config.Nodes["job"]
+	assert.True(t, ok, "job should appear as a descendant of cronjob")
+	_, ok = rvCC.Config.Nodes["pod"]
+	assert.True(t, ok, "pod should appear as a descendant of job")
+}
+
+// Test_ComponentCache_visit_DaemonSet_withPod covers DaemonSet -> Pod, with
+// the queryer mock returning a real Pod child so the descendant actually
+// shows up in the graph.
+func Test_ComponentCache_visit_DaemonSet_withPod(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "daemonset", UID: types.UID("daemonset")},
+	}
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: types.UID("pod")},
+	}
+
+	ctx := context.TODO()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dashConfig := configFake.NewMockDash(ctrl)
+	dashConfig.EXPECT().
+		ObjectPath(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("/path", nil).
+		AnyTimes()
+
+	o := storeFake.NewMockObjectStore(ctrl)
+	dashConfig.EXPECT().ObjectStore().Return(o).AnyTimes()
+
+	q := queryerFake.NewMockQueryer(ctrl)
+	q.EXPECT().
+		Children(gomock.Any(), testutil.ToUnstructured(t, daemonSet)).
+		Return([]*unstructured.Unstructured{testutil.ToUnstructured(t, pod)}, nil)
+
+	components, err := lru.New(100)
+	require.NoError(t, err)
+
+	c := &componentCache{
+		components: components,
+		dashConfig: dashConfig,
+	}
+	c.SetQueryer(q)
+
+	rv, err := c.newResourceViewer(ctx)
+	require.NoError(t, err)
+
+	key, err := objectstoreutil.KeyFromObject(daemonSet)
+	require.NoError(t, err)
+
+	done, _ := c.visit(ctx, key, daemonSet, rv)
+	newKey := <-done
+
+	cc, err := c.getComponent(ctx, newKey, daemonSet, rv)
+	require.NoError(t, err)
+
+	rvCC := cc.(*component.ResourceViewer)
+	_, ok := rvCC.Config.Nodes["daemonset"]
+	assert.True(t, ok)
+	_, ok = rvCC.Config.Nodes["pod"]
+	assert.True(t, ok, "pod should appear as a descendant of daemonset")
+}
@@ -0,0 +1,23 @@
+package queryer
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Queryer answers questions about how objects in a cluster relate to each
+// other. It backs the resource viewer's graph traversal.
+type Queryer interface {
+	// Children returns the children of object, discovered via owner
+	// references and kind-specific heuristics (e.g. label selectors).
+	Children(ctx context.Context, object *unstructured.Unstructured) ([]*unstructured.Unstructured, error)
+
+	// ChildrenMeta returns the same children as Children, but as partial
+	// object metadata (GVK, name/namespace, labels, annotations, owner
+	// references, and UID only). It is used for the resource viewer's
+	// metadata-only traversal mode, which is much cheaper on namespaces
+	// with many objects since it avoids fetching full object bodies.
+	ChildrenMeta(ctx context.Context, object *unstructured.Unstructured) ([]*metav1.PartialObjectMetadata, error)
+}